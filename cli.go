@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+)
+
+// stringSliceFlag collects repeated occurrences of a flag (e.g. multiple
+// `--doc` arguments) into a single slice.
+type stringSliceFlag []string
+
+func (s *stringSliceFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringSliceFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// Config holds the resolved command-line options for a single run of the
+// exporter.
+type Config struct {
+	DocIDs     []string
+	FolderID   string
+	StorageURL string
+	Force      bool
+}
+
+func parseFlags(args []string) (*Config, error) {
+	fs := flag.NewFlagSet("docs2md", flag.ExitOnError)
+
+	var docIDs stringSliceFlag
+	fs.Var(&docIDs, "doc", "Google Doc ID to convert (repeatable)")
+	folder := fs.String("folder", "", "Google Drive folder ID to recursively export")
+	out := fs.String("out", "tmp", "output directory for Markdown and assets (used when --storage is not set)")
+	storage := fs.String("storage", "", `output backend, e.g. "fs://tmp" or "s3://bucket/prefix?endpoint=http://localhost:9000" (defaults to fs://<out>)`)
+	force := fs.Bool("force", false, "overwrite existing output files")
+
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	storageURL := *storage
+	if storageURL == "" {
+		storageURL = "fs://" + *out
+	}
+
+	cfg := &Config{
+		DocIDs:     docIDs,
+		FolderID:   *folder,
+		StorageURL: storageURL,
+		Force:      *force,
+	}
+
+	if len(cfg.DocIDs) == 0 && cfg.FolderID == "" {
+		return nil, fmt.Errorf("at least one of --doc or --folder must be given")
+	}
+
+	return cfg, nil
+}
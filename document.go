@@ -0,0 +1,543 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/docs/v1"
+)
+
+type Element interface {
+	Markdown() string
+}
+
+type DocumentImage struct {
+	ContentURI  string
+	Description string
+	ObjectID    string
+}
+
+// HeadingElement renders a Google Docs TITLE or HEADING_1..HEADING_6
+// paragraph as a Markdown ATX heading.
+type HeadingElement struct {
+	Level int
+	Body  string
+}
+
+func (e HeadingElement) Markdown() string {
+	level := e.Level
+	if level < 1 {
+		level = 1
+	}
+	if level > 6 {
+		level = 6
+	}
+	return fmt.Sprintf("%s %s\n", strings.Repeat("#", level), e.Body)
+}
+
+type TextElement struct {
+	Body string
+}
+
+func (e TextElement) Markdown() string {
+	if e.Body == "" {
+		return "\n"
+	}
+	return fmt.Sprintf("%s\n", e.Body)
+}
+
+// ListItemElement renders a bulleted or numbered paragraph, indented
+// according to its nesting level.
+type ListItemElement struct {
+	Body    string
+	Ordered bool
+	Level   int
+	Index   int
+}
+
+func (e ListItemElement) Markdown() string {
+	indent := strings.Repeat("  ", e.Level)
+	if e.Ordered {
+		return fmt.Sprintf("%s%d. %s\n", indent, e.Index, e.Body)
+	}
+	return fmt.Sprintf("%s- %s\n", indent, e.Body)
+}
+
+// CodeBlockElement renders a paragraph that is entirely set in a monospace
+// font as a fenced code block.
+type CodeBlockElement struct {
+	Body string
+}
+
+func (e CodeBlockElement) Markdown() string {
+	return fmt.Sprintf("```\n%s\n```\n", e.Body)
+}
+
+type HorizontalRuleElement struct{}
+
+func (e HorizontalRuleElement) Markdown() string {
+	return "---\n"
+}
+
+type PageBreakElement struct{}
+
+func (e PageBreakElement) Markdown() string {
+	return "\n"
+}
+
+// TableElement renders a docs.Table as a GFM pipe table. Rows[0] is the
+// header row.
+type TableElement struct {
+	Rows [][]string
+}
+
+func (e TableElement) Markdown() string {
+	if len(e.Rows) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	header := e.Rows[0]
+	b.WriteString("| " + strings.Join(header, " | ") + " |\n")
+
+	seps := make([]string, len(header))
+	for i := range seps {
+		seps[i] = "---"
+	}
+	b.WriteString("| " + strings.Join(seps, " | ") + " |\n")
+
+	for _, row := range e.Rows[1:] {
+		b.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+
+	return b.String()
+}
+
+// imageToken returns a sentinel placeholder embedded inline in a paragraph's
+// rendered body where an image belongs. The object's real file extension
+// isn't known until it's downloaded, so WriteFiles replaces each token with a
+// Markdown image link once that image has been fetched.
+func imageToken(objectID string) string {
+	return fmt.Sprintf("\x00IMG:%s\x00", objectID)
+}
+
+func imageMarkdown(image DocumentImage, filename string) string {
+	return fmt.Sprintf("![%s](%s)", image.Description, filename)
+}
+
+// listCounterKey identifies one nesting level of one Google Docs list, used
+// to track ordinals for numbered lists as we walk the document in order.
+type listCounterKey struct {
+	listID string
+	level  int64
+}
+
+type Document struct {
+	Title            string
+	Elements         []Element
+	Images           map[string]DocumentImage
+	originalDocument *docs.Document
+	namedStyles      map[string]*docs.NamedStyle
+	listCounters     map[listCounterKey]int
+}
+
+func (d *Document) Parse(doc *docs.Document) error {
+	d.originalDocument = doc
+
+	d.Title = doc.Title
+	d.namedStyles = map[string]*docs.NamedStyle{}
+	if doc.NamedStyles != nil {
+		for _, s := range doc.NamedStyles.Styles {
+			d.namedStyles[s.NamedStyleType] = s
+		}
+	}
+
+	for _, b := range doc.Body.Content {
+		err := d.parseBody(b)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (d *Document) parseBody(elm *docs.StructuralElement) error {
+	if elm == nil {
+		return nil
+	}
+
+	if elm.Table != nil {
+		d.handleTable(elm.Table)
+		return nil
+	}
+
+	paragraph := elm.Paragraph
+	if paragraph == nil {
+		return nil
+	}
+
+	return d.parseParagraph(paragraph)
+}
+
+func (d *Document) parseParagraph(paragraph *docs.Paragraph) error {
+	body, monospace := d.renderParagraphBody(paragraph)
+
+	// A paragraph made up of only a PageBreak or HorizontalRule carries no
+	// text of its own.
+	if body == "" {
+		for _, e := range paragraph.Elements {
+			if e.PageBreak != nil {
+				d.add(PageBreakElement{})
+				d.addPositionedObjects(paragraph)
+				return nil
+			}
+			if e.HorizontalRule != nil {
+				d.add(HorizontalRuleElement{})
+				d.addPositionedObjects(paragraph)
+				return nil
+			}
+		}
+	}
+
+	if paragraph.Bullet != nil {
+		d.add(d.listItem(paragraph.Bullet, body))
+		d.addPositionedObjects(paragraph)
+		return nil
+	}
+
+	if level, ok := headingLevel(namedStyleType(paragraph)); ok {
+		d.add(HeadingElement{Level: level, Body: body})
+		d.addPositionedObjects(paragraph)
+		return nil
+	}
+
+	if monospace && body != "" {
+		d.add(CodeBlockElement{Body: body})
+		d.addPositionedObjects(paragraph)
+		return nil
+	}
+
+	d.add(TextElement{Body: body})
+	d.addPositionedObjects(paragraph)
+
+	return nil
+}
+
+// addPositionedObjects resolves every image anchored to the paragraph via
+// PositionedObjectIds and appends it as its own TextElement. Unlike inline
+// objects, positioned objects aren't part of any paragraph's text flow, so
+// they're handled once here regardless of which kind of element the
+// paragraph turned into above.
+func (d *Document) addPositionedObjects(paragraph *docs.Paragraph) {
+	for _, objectID := range paragraph.PositionedObjectIds {
+		if img := d.handlePositionedObjectElement(objectID); img != "" {
+			d.add(TextElement{Body: img})
+		}
+	}
+}
+
+// renderParagraphBody concatenates the paragraph's TextRuns into a single
+// Markdown-inline string, and also reports whether every run with content
+// was set in a monospace font (a signal that the whole paragraph is really
+// a code block rather than regular text, in which case its runs are
+// rendered as plain text rather than individually backtick-wrapped).
+func (d *Document) renderParagraphBody(paragraph *docs.Paragraph) (string, bool) {
+	monospace := d.isMonospaceParagraph(paragraph)
+
+	var b strings.Builder
+	for _, e := range paragraph.Elements {
+		if e.TextRun != nil {
+			content := strings.TrimRight(e.TextRun.Content, "\n")
+			if content == "" {
+				continue
+			}
+			if monospace {
+				b.WriteString(content)
+			} else {
+				b.WriteString(renderTextRun(e.TextRun.TextStyle, content))
+			}
+			continue
+		}
+
+		if e.InlineObjectElement != nil {
+			if img := d.handleInlineObjectElement(e.InlineObjectElement); img != "" {
+				b.WriteString(img)
+			}
+			continue
+		}
+	}
+
+	return b.String(), monospace
+}
+
+// isMonospaceParagraph reports whether every run with content in the
+// paragraph is set in a monospace font, and there is at least one such run.
+func (d *Document) isMonospaceParagraph(paragraph *docs.Paragraph) bool {
+	sawContent := false
+
+	for _, e := range paragraph.Elements {
+		if e.TextRun != nil {
+			content := strings.TrimRight(e.TextRun.Content, "\n")
+			if content == "" {
+				continue
+			}
+			sawContent = true
+			if !d.isMonospace(paragraph, e.TextRun.TextStyle) {
+				return false
+			}
+			continue
+		}
+
+		if e.InlineObjectElement != nil {
+			return false
+		}
+	}
+
+	return sawContent
+}
+
+// renderTextRun applies the Markdown equivalent of a TextRun's inline
+// styling: bold, italic, strikethrough, inline code, and hyperlinks.
+func renderTextRun(ts *docs.TextStyle, content string) string {
+	if ts == nil {
+		return content
+	}
+
+	if isMonospace(ts) {
+		return fmt.Sprintf("`%s`", content)
+	}
+
+	text := content
+	if ts.Bold {
+		text = fmt.Sprintf("**%s**", text)
+	}
+	if ts.Italic {
+		text = fmt.Sprintf("*%s*", text)
+	}
+	if ts.Strikethrough {
+		text = fmt.Sprintf("~~%s~~", text)
+	}
+	if ts.Link != nil && ts.Link.Url != "" {
+		text = fmt.Sprintf("[%s](%s)", text, ts.Link.Url)
+	}
+	return text
+}
+
+func isMonospace(ts *docs.TextStyle) bool {
+	if ts == nil || ts.WeightedFontFamily == nil {
+		return false
+	}
+	family := strings.ToLower(ts.WeightedFontFamily.FontFamily)
+	return strings.Contains(family, "courier") || strings.Contains(family, "consolas") || strings.Contains(family, "mono")
+}
+
+// isMonospace resolves whether a run is monospace, falling back to the
+// document's NamedStyles when the run doesn't set its own font family.
+func (d *Document) isMonospace(paragraph *docs.Paragraph, ts *docs.TextStyle) bool {
+	if ts != nil && ts.WeightedFontFamily != nil {
+		return isMonospace(ts)
+	}
+	if ns := d.namedStyleFor(paragraph); ns != nil {
+		return isMonospace(ns.TextStyle)
+	}
+	return false
+}
+
+func (d *Document) namedStyleFor(paragraph *docs.Paragraph) *docs.NamedStyle {
+	if paragraph.ParagraphStyle == nil {
+		return nil
+	}
+	return d.namedStyles[paragraph.ParagraphStyle.NamedStyleType]
+}
+
+func namedStyleType(paragraph *docs.Paragraph) string {
+	if paragraph.ParagraphStyle == nil {
+		return ""
+	}
+	return paragraph.ParagraphStyle.NamedStyleType
+}
+
+func headingLevel(namedStyleType string) (int, bool) {
+	switch namedStyleType {
+	case "TITLE":
+		return 1, true
+	case "HEADING_1":
+		return 1, true
+	case "HEADING_2":
+		return 2, true
+	case "HEADING_3":
+		return 3, true
+	case "HEADING_4":
+		return 4, true
+	case "HEADING_5":
+		return 5, true
+	case "HEADING_6":
+		return 6, true
+	default:
+		return 0, false
+	}
+}
+
+// listItem builds a ListItemElement for a bulleted paragraph, tracking
+// per-list, per-level ordinals so numbered lists render correctly.
+func (d *Document) listItem(bullet *docs.Bullet, body string) ListItemElement {
+	level := bullet.NestingLevel
+	ordered := d.isOrderedList(bullet.ListId, level)
+
+	index := 0
+	if ordered {
+		index = d.nextOrdinal(bullet.ListId, level)
+	}
+
+	return ListItemElement{Body: body, Ordered: ordered, Level: int(level), Index: index}
+}
+
+func (d *Document) isOrderedList(listID string, level int64) bool {
+	list, ok := d.originalDocument.Lists[listID]
+	if !ok || list.ListProperties == nil {
+		return false
+	}
+	levels := list.ListProperties.NestingLevels
+	if level < 0 || int(level) >= len(levels) {
+		return false
+	}
+	return levels[level].GlyphType != ""
+}
+
+func (d *Document) nextOrdinal(listID string, level int64) int {
+	if d.listCounters == nil {
+		d.listCounters = map[listCounterKey]int{}
+	}
+
+	for k := range d.listCounters {
+		if k.listID == listID && k.level > level {
+			delete(d.listCounters, k)
+		}
+	}
+
+	key := listCounterKey{listID: listID, level: level}
+	d.listCounters[key]++
+	return d.listCounters[key]
+}
+
+func (d *Document) handleTable(table *docs.Table) {
+	var rows [][]string
+	for _, row := range table.TableRows {
+		var cols []string
+		for _, cell := range row.TableCells {
+			cols = append(cols, d.renderCell(cell))
+		}
+		rows = append(rows, cols)
+	}
+	d.add(TableElement{Rows: rows})
+}
+
+// renderCell flattens a table cell's paragraphs into a single line, since
+// GFM pipe tables cannot contain block-level content. Literal "|" and
+// newlines are escaped so a cell's content can't be mistaken for column
+// separators or break the row onto multiple lines.
+func (d *Document) renderCell(cell *docs.TableCell) string {
+	var parts []string
+	for _, c := range cell.Content {
+		if c.Paragraph == nil {
+			continue
+		}
+		body, _ := d.renderParagraphBody(c.Paragraph)
+		if body != "" {
+			parts = append(parts, body)
+		}
+	}
+	return escapeTableCell(strings.Join(parts, " "))
+}
+
+func escapeTableCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	s = strings.ReplaceAll(s, "\n", "<br>")
+	return s
+}
+
+func (d *Document) add(elm Element) {
+	d.Elements = append(d.Elements, elm)
+}
+
+func (d *Document) handleInlineObjectElement(elm *docs.InlineObjectElement) string {
+	inlineObject, ok := d.originalDocument.InlineObjects[elm.InlineObjectId]
+	if !ok {
+		return ""
+	}
+
+	pro := inlineObject.InlineObjectProperties
+	if pro == nil {
+		return ""
+	}
+
+	obj := pro.EmbeddedObject
+	if obj == nil {
+		return ""
+	}
+
+	im := obj.ImageProperties
+	if im == nil {
+		return ""
+	}
+
+	image := DocumentImage{ObjectID: inlineObject.ObjectId, ContentURI: im.ContentUri, Description: obj.Description}
+	d.registerImage(image)
+
+	return imageToken(image.ObjectID)
+}
+
+// handlePositionedObjectElement resolves an image anchored to a paragraph
+// via PositionedObjectIds rather than inline in its text flow.
+func (d *Document) handlePositionedObjectElement(objectID string) string {
+	positionedObject, ok := d.originalDocument.PositionedObjects[objectID]
+	if !ok {
+		return ""
+	}
+
+	pro := positionedObject.PositionedObjectProperties
+	if pro == nil {
+		return ""
+	}
+
+	obj := pro.EmbeddedObject
+	if obj == nil {
+		return ""
+	}
+
+	im := obj.ImageProperties
+	if im == nil {
+		return ""
+	}
+
+	image := DocumentImage{ObjectID: positionedObject.ObjectId, ContentURI: im.ContentUri, Description: obj.Description}
+	d.registerImage(image)
+
+	return imageToken(image.ObjectID)
+}
+
+func (d *Document) registerImage(image DocumentImage) {
+	if d.Images == nil {
+		d.Images = map[string]DocumentImage{}
+	}
+	d.Images[image.ObjectID] = image
+}
+
+func (d *Document) WriteFiles(storage Storage, images *ImageStore) error {
+	var b strings.Builder
+	for _, elm := range d.Elements {
+		b.WriteString(elm.Markdown())
+	}
+	body := b.String()
+
+	for _, image := range d.Images {
+		filename, err := images.Resolve(image, storage)
+		if err != nil {
+			return err
+		}
+		body = strings.ReplaceAll(body, imageToken(image.ObjectID), imageMarkdown(image, filename))
+	}
+
+	return storage.PutMarkdown(fmt.Sprintf("%s.md", d.Title), strings.NewReader(body))
+}
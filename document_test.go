@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"google.golang.org/api/docs/v1"
+)
+
+func loadFixture(t *testing.T, name string) *docs.Document {
+	t.Helper()
+
+	data, err := ioutil.ReadFile("testdata/" + name)
+	if err != nil {
+		t.Fatalf("failed to read fixture %s: %v", name, err)
+	}
+
+	doc := &docs.Document{}
+	if err := json.Unmarshal(data, doc); err != nil {
+		t.Fatalf("failed to unmarshal fixture %s: %v", name, err)
+	}
+
+	return doc
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		fixture  string
+		expected string
+	}{
+		{
+			name:     "heading levels",
+			fixture:  "heading.json",
+			expected: "## Hello World\n",
+		},
+		{
+			name:     "inline styles and links",
+			fixture:  "inline.json",
+			expected: "**bold** *and italic* and a [link](https://example.com).\n",
+		},
+		{
+			name:     "ordered and unordered lists",
+			fixture:  "list.json",
+			expected: "1. first\n2. second\n- bullet item\n",
+		},
+		{
+			name:     "tables with an escaped pipe",
+			fixture:  "table.json",
+			expected: "| A | has \\| pipe |\n| --- | --- |\n| 1 | 2 |\n",
+		},
+		{
+			name:     "monospace paragraph becomes a fenced code block",
+			fixture:  "codeblock.json",
+			expected: "```\nfmt.Println(\"hi\")\n```\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := loadFixture(t, tt.fixture)
+
+			d := &Document{}
+			if err := d.Parse(doc); err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+
+			var b strings.Builder
+			for _, elm := range d.Elements {
+				b.WriteString(elm.Markdown())
+			}
+
+			if got := b.String(); got != tt.expected {
+				t.Errorf("got markdown:\n%q\nwant:\n%q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestParsePositionedObjectOnHeading(t *testing.T) {
+	doc := loadFixture(t, "positioned_image.json")
+
+	d := &Document{}
+	if err := d.Parse(doc); err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+
+	if len(d.Images) != 1 {
+		t.Fatalf("got %d images, want 1 (PositionedObjectIds on a heading paragraph should still be resolved)", len(d.Images))
+	}
+
+	image, ok := d.Images["obj1"]
+	if !ok {
+		t.Fatalf("expected image obj1 to be registered, got %+v", d.Images)
+	}
+	if image.ContentURI != "https://example.com/diagram.png" {
+		t.Errorf("got ContentURI %q, want %q", image.ContentURI, "https://example.com/diagram.png")
+	}
+
+	var b strings.Builder
+	for _, elm := range d.Elements {
+		b.WriteString(elm.Markdown())
+	}
+
+	want := "# Figure\n" + imageToken("obj1") + "\n"
+	if got := b.String(); got != want {
+		t.Errorf("got markdown:\n%q\nwant:\n%q", got, want)
+	}
+}
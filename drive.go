@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/drive/v3"
+)
+
+const (
+	mimeTypeFolder = "application/vnd.google-apps.folder"
+	mimeTypeDoc    = "application/vnd.google-apps.document"
+)
+
+// listDocsInFolder recursively walks the Drive folder identified by
+// folderID and returns the IDs of every Google Doc found within it or any
+// of its subfolders.
+func listDocsInFolder(ctx context.Context, srv *drive.Service, folderID string) ([]string, error) {
+	var docIDs []string
+
+	children, err := listFolderChildren(ctx, srv, folderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, f := range children {
+		switch f.MimeType {
+		case mimeTypeFolder:
+			sub, err := listDocsInFolder(ctx, srv, f.Id)
+			if err != nil {
+				return nil, err
+			}
+			docIDs = append(docIDs, sub...)
+		case mimeTypeDoc:
+			docIDs = append(docIDs, f.Id)
+		}
+	}
+
+	return docIDs, nil
+}
+
+// listFolderChildren returns the immediate children of a Drive folder,
+// paging through results as needed.
+func listFolderChildren(ctx context.Context, srv *drive.Service, folderID string) ([]*drive.File, error) {
+	var files []*drive.File
+
+	q := fmt.Sprintf("'%s' in parents and trashed = false", folderID)
+	pageToken := ""
+	for {
+		call := srv.Files.List().
+			Context(ctx).
+			Q(q).
+			Fields("nextPageToken, files(id, name, mimeType)")
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		res, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list folder %s: %w", folderID, err)
+		}
+		files = append(files, res.Files...)
+
+		if res.NextPageToken == "" {
+			break
+		}
+		pageToken = res.NextPageToken
+	}
+
+	return files, nil
+}
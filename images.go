@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// extensionByContentType maps the handful of image formats Google Docs
+// commonly embeds to a file extension, preferring well-known short forms
+// over whatever mime.ExtensionsByType might otherwise pick.
+var extensionByContentType = map[string]string{
+	"image/png":     "png",
+	"image/jpeg":    "jpg",
+	"image/gif":     "gif",
+	"image/webp":    "webp",
+	"image/svg+xml": "svg",
+}
+
+func extensionForContentType(contentType string) string {
+	contentType = strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0])
+	if ext, ok := extensionByContentType[contentType]; ok {
+		return ext
+	}
+
+	if exts, err := mime.ExtensionsByType(contentType); err == nil && len(exts) > 0 {
+		return strings.TrimPrefix(exts[0], ".")
+	}
+
+	return "png"
+}
+
+// ImageStore downloads and writes the media assets referenced by one or
+// more Documents across a batch run. Each content URI is downloaded once,
+// and images with identical content (by SHA-256 hash) are written once and
+// share one filename. The same image reached through a different URI is
+// downloaded again before its hash is known.
+type ImageStore struct {
+	mu     sync.Mutex
+	byURI  map[string]string
+	byHash map[string]string
+}
+
+func NewImageStore() *ImageStore {
+	return &ImageStore{
+		byURI:  map[string]string{},
+		byHash: map[string]string{},
+	}
+}
+
+// Resolve returns the filename an image was (or will be) written to in
+// storage, fetching it the first time its URI is seen and writing it the
+// first time its content is seen.
+func (s *ImageStore) Resolve(image DocumentImage, storage Storage) (string, error) {
+	s.mu.Lock()
+	if fname, ok := s.byURI[image.ContentURI]; ok {
+		s.mu.Unlock()
+		return fname, nil
+	}
+	s.mu.Unlock()
+
+	resp, err := http.Get(image.ContentURI)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+	ext := extensionForContentType(contentType)
+
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fname, ok := s.byHash[hash]; ok {
+		s.byURI[image.ContentURI] = fname
+		return fname, nil
+	}
+
+	fname := fmt.Sprintf("%s.%s", image.ObjectID, ext)
+	if err := storage.PutAsset(fname, contentType, bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+
+	s.byURI[image.ContentURI] = fname
+	s.byHash[hash] = fname
+
+	return fname, nil
+}
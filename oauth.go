@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os/exec"
+	"runtime"
+
+	"golang.org/x/oauth2"
+)
+
+// generateRandomString returns a URL-safe base64 string with n random bytes
+// of entropy, suitable for use as an OAuth state value or PKCE verifier.
+func generateRandomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// codeChallengeS256 derives the PKCE code_challenge for the given verifier
+// using the S256 transform.
+func codeChallengeS256(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// openBrowser best-effort opens url in the user's default browser.
+func openBrowser(url string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		return exec.Command("open", url).Start()
+	case "windows":
+		return exec.Command("rundll32", "url.dll,FileProtocolHandler", url).Start()
+	default:
+		return exec.Command("xdg-open", url).Start()
+	}
+}
+
+// getTokenFromWeb runs the OAuth2 authorization code flow with PKCE,
+// receiving the authorization code via a local loopback HTTP server instead
+// of asking the user to paste it in by hand.
+func getTokenFromWeb(config *oauth2.Config) *oauth2.Token {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatalf("Unable to start local callback server: %v", err)
+	}
+	defer listener.Close()
+
+	port := listener.Addr().(*net.TCPAddr).Port
+	config.RedirectURL = fmt.Sprintf("http://localhost:%d/callback", port)
+
+	state, err := generateRandomString(16)
+	if err != nil {
+		log.Fatalf("Unable to generate state: %v", err)
+	}
+	verifier, err := generateRandomString(32)
+	if err != nil {
+		log.Fatalf("Unable to generate code verifier: %v", err)
+	}
+	challenge := codeChallengeS256(verifier)
+
+	authURL := config.AuthCodeURL(state,
+		oauth2.AccessTypeOffline,
+		oauth2.SetAuthURLParam("code_challenge", challenge),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	)
+
+	type result struct {
+		code string
+		err  error
+	}
+	resultCh := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if errParam := q.Get("error"); errParam != "" {
+			resultCh <- result{err: fmt.Errorf("authorization failed: %s", errParam)}
+			fmt.Fprintln(w, "Authorization failed. You can close this tab.")
+			return
+		}
+		if q.Get("state") != state {
+			resultCh <- result{err: fmt.Errorf("state mismatch")}
+			fmt.Fprintln(w, "State mismatch. You can close this tab.")
+			return
+		}
+		code := q.Get("code")
+		if code == "" {
+			resultCh <- result{err: fmt.Errorf("no code in callback request")}
+			fmt.Fprintln(w, "Missing authorization code. You can close this tab.")
+			return
+		}
+		resultCh <- result{code: code}
+		fmt.Fprintln(w, "Authorization complete. You can close this tab and return to the terminal.")
+	})
+
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(listener)
+	defer srv.Shutdown(context.Background())
+
+	fmt.Printf("Opening browser for authorization. If it doesn't open, go to the following link:\n%v\n", authURL)
+	if err := openBrowser(authURL); err != nil {
+		fmt.Printf("Unable to open browser automatically: %v\n", err)
+	}
+
+	res := <-resultCh
+	if res.err != nil {
+		log.Fatalf("Unable to complete authorization: %v", res.err)
+	}
+
+	tok, err := config.Exchange(context.Background(), res.code,
+		oauth2.SetAuthURLParam("code_verifier", verifier),
+	)
+	if err != nil {
+		log.Fatalf("Unable to retrieve token from web: %v", err)
+	}
+	return tok
+}
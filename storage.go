@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// Storage is the write path for everything docs2md produces: the rendered
+// Markdown file and the media assets it references. Implementations let the
+// exporter feed a static-site pipeline, a CMS media store, or a test's
+// in-memory buffer without the rest of the code caring which.
+type Storage interface {
+	PutMarkdown(name string, r io.Reader) error
+	PutAsset(name, contentType string, r io.Reader) error
+}
+
+// sanitizeStorageName clamps a caller-supplied name (e.g. a Document title,
+// which may come from a Drive folder the operator doesn't control) to a
+// safe basename, so a title like "../../etc/evil" can't escape the
+// configured output directory or bucket prefix.
+func sanitizeStorageName(name string) string {
+	cleaned := path.Base(path.Clean("/" + strings.ReplaceAll(name, "\\", "/")))
+	if cleaned == "" || cleaned == "." || cleaned == "/" {
+		return "untitled"
+	}
+	return cleaned
+}
+
+// NewStorage parses a --storage URL such as "fs://tmp" or
+// "s3://bucket/prefix?endpoint=http://localhost:9000&region=us-east-1" and
+// returns the backend it describes.
+func NewStorage(ctx context.Context, rawURL string, force bool) (Storage, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --storage value %q: %w", rawURL, err)
+	}
+
+	switch u.Scheme {
+	case "", "fs":
+		dir := path.Join(u.Host, u.Path)
+		return NewFSStorage(dir, force)
+	case "s3":
+		bucket := u.Host
+		prefix := strings.TrimPrefix(u.Path, "/")
+		return NewS3Storage(ctx, bucket, prefix, u.Query().Get("endpoint"), u.Query().Get("region"), force)
+	default:
+		return nil, fmt.Errorf("unsupported --storage scheme %q", u.Scheme)
+	}
+}
+
+// FSStorage writes Markdown and assets to a directory on the local
+// filesystem.
+type FSStorage struct {
+	Dir   string
+	Force bool
+}
+
+func NewFSStorage(dir string, force bool) (*FSStorage, error) {
+	i, err := os.Stat(dir)
+	if err != nil {
+		return nil, err
+	}
+	if !i.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", dir)
+	}
+	return &FSStorage{Dir: dir, Force: force}, nil
+}
+
+func (s *FSStorage) PutMarkdown(name string, r io.Reader) error {
+	return s.put(name, r)
+}
+
+func (s *FSStorage) PutAsset(name, contentType string, r io.Reader) error {
+	return s.put(name, r)
+}
+
+func (s *FSStorage) put(name string, r io.Reader) error {
+	dst := path.Join(s.Dir, sanitizeStorageName(name))
+
+	if !s.Force {
+		if _, err := os.Stat(dst); err == nil {
+			return fmt.Errorf("%s already exists, use --force to overwrite", dst)
+		}
+	}
+
+	f, err := os.OpenFile(dst, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0755)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(f, r)
+	return err
+}
+
+// MemoryStorage keeps everything written to it in memory, so the write
+// path can be exercised in tests without touching disk or the network.
+type MemoryStorage struct {
+	mu       sync.Mutex
+	Force    bool
+	Markdown map[string][]byte
+	Assets   map[string][]byte
+}
+
+func NewMemoryStorage(force bool) *MemoryStorage {
+	return &MemoryStorage{
+		Force:    force,
+		Markdown: map[string][]byte{},
+		Assets:   map[string][]byte{},
+	}
+}
+
+func (s *MemoryStorage) PutMarkdown(name string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	name = sanitizeStorageName(name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.Force {
+		if _, ok := s.Markdown[name]; ok {
+			return fmt.Errorf("%s already exists, use --force to overwrite", name)
+		}
+	}
+	s.Markdown[name] = data
+	return nil
+}
+
+func (s *MemoryStorage) PutAsset(name, contentType string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	name = sanitizeStorageName(name)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.Force {
+		if _, ok := s.Assets[name]; ok {
+			return fmt.Errorf("%s already exists, use --force to overwrite", name)
+		}
+	}
+	s.Assets[name] = data
+	return nil
+}
+
+// S3Storage writes Markdown and assets as objects in an S3-compatible
+// bucket. A custom endpoint can be supplied so it also works against MinIO.
+type S3Storage struct {
+	client *s3.Client
+	bucket string
+	prefix string
+	force  bool
+}
+
+func NewS3Storage(ctx context.Context, bucket, prefix, endpoint, region string, force bool) (*S3Storage, error) {
+	var opts []func(*config.LoadOptions) error
+	if region != "" {
+		opts = append(opts, config.WithRegion(region))
+	}
+
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load AWS config: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+
+	return &S3Storage{client: client, bucket: bucket, prefix: prefix, force: force}, nil
+}
+
+func (s *S3Storage) PutMarkdown(name string, r io.Reader) error {
+	return s.putObject(name, "text/markdown; charset=utf-8", r)
+}
+
+func (s *S3Storage) PutAsset(name, contentType string, r io.Reader) error {
+	return s.putObject(name, contentType, r)
+}
+
+func (s *S3Storage) putObject(name, contentType string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	key := path.Join(s.prefix, sanitizeStorageName(name))
+
+	if !s.force {
+		_, headErr := s.client.HeadObject(context.Background(), &s3.HeadObjectInput{
+			Bucket: aws.String(s.bucket),
+			Key:    aws.String(key),
+		})
+		if headErr == nil {
+			return fmt.Errorf("s3://%s/%s already exists, use --force to overwrite", s.bucket, key)
+		}
+	}
+
+	_, err = s.client.PutObject(context.Background(), &s3.PutObjectInput{
+		Bucket:      aws.String(s.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSanitizeStorageName(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "plain name", in: "My Doc.md", want: "My Doc.md"},
+		{name: "parent traversal", in: "../../etc/evil", want: "evil"},
+		{name: "nested path kept as basename", in: "a/b/c.md", want: "c.md"},
+		{name: "windows-style traversal", in: "..\\..\\evil", want: "evil"},
+		{name: "only dots", in: "..", want: "untitled"},
+		{name: "empty", in: "", want: "untitled"},
+		{name: "root", in: "/", want: "untitled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sanitizeStorageName(tt.in); got != tt.want {
+				t.Errorf("sanitizeStorageName(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStoragePutMarkdown(t *testing.T) {
+	s := NewMemoryStorage(false)
+
+	if err := s.PutMarkdown("notes.md", strings.NewReader("hello")); err != nil {
+		t.Fatalf("PutMarkdown returned error: %v", err)
+	}
+
+	got, ok := s.Markdown["notes.md"]
+	if !ok {
+		t.Fatalf("expected notes.md to be stored, got %v", s.Markdown)
+	}
+	if string(got) != "hello" {
+		t.Errorf("got content %q, want %q", got, "hello")
+	}
+}
+
+func TestMemoryStoragePutMarkdownSanitizesName(t *testing.T) {
+	s := NewMemoryStorage(false)
+
+	if err := s.PutMarkdown("../../etc/evil.md", strings.NewReader("hi")); err != nil {
+		t.Fatalf("PutMarkdown returned error: %v", err)
+	}
+
+	if _, ok := s.Markdown["../../etc/evil.md"]; ok {
+		t.Fatalf("unsanitized name should not be used as the storage key")
+	}
+	if _, ok := s.Markdown["evil.md"]; !ok {
+		t.Fatalf("expected sanitized key %q, got %v", "evil.md", s.Markdown)
+	}
+}
+
+func TestMemoryStorageForce(t *testing.T) {
+	withoutForce := NewMemoryStorage(false)
+	if err := withoutForce.PutAsset("img.png", "image/png", strings.NewReader("a")); err != nil {
+		t.Fatalf("first PutAsset returned error: %v", err)
+	}
+	if err := withoutForce.PutAsset("img.png", "image/png", strings.NewReader("b")); err == nil {
+		t.Fatalf("expected second PutAsset without --force to fail on existing key")
+	}
+	if got := string(withoutForce.Assets["img.png"]); got != "a" {
+		t.Errorf("existing asset should be untouched after rejected overwrite, got %q", got)
+	}
+
+	withForce := NewMemoryStorage(true)
+	if err := withForce.PutAsset("img.png", "image/png", strings.NewReader("a")); err != nil {
+		t.Fatalf("first PutAsset returned error: %v", err)
+	}
+	if err := withForce.PutAsset("img.png", "image/png", strings.NewReader("b")); err != nil {
+		t.Fatalf("PutAsset with --force should overwrite, got error: %v", err)
+	}
+	if got := string(withForce.Assets["img.png"]); got != "b" {
+		t.Errorf("got %q after forced overwrite, want %q", got, "b")
+	}
+}
+
+func TestNewStorageFSScheme(t *testing.T) {
+	dir := t.TempDir()
+
+	storage, err := NewStorage(nil, "fs://"+dir, false)
+	if err != nil {
+		t.Fatalf("NewStorage returned error: %v", err)
+	}
+
+	fsStorage, ok := storage.(*FSStorage)
+	if !ok {
+		t.Fatalf("got %T, want *FSStorage", storage)
+	}
+	if fsStorage.Dir != dir {
+		t.Errorf("got Dir %q, want %q", fsStorage.Dir, dir)
+	}
+}
+
+func TestNewStorageUnsupportedScheme(t *testing.T) {
+	if _, err := NewStorage(nil, "ftp://example.com", false); err == nil {
+		t.Fatalf("expected an error for an unsupported --storage scheme")
+	}
+}